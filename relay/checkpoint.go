@@ -0,0 +1,119 @@
+package relay
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	checkpointBucket  = []byte("cursor")
+	cursorKey         = []byte("last")
+	coordinatorBucket = []byte("coordinator")
+	nonceKey          = []byte("nonce")
+)
+
+// Cursor is the durable progress marker a Checkpointer persists once a
+// batch has been fully committed to the side chain. A batch is only ever
+// saved after every tx it produced has been confirmed mined, so resuming
+// at LastBlockIndex+1 never re-does partial work -- there is no per-task
+// granularity to track.
+type Cursor struct {
+	LastBlockIndex     uint32 `json:"lastBlockIndex"`
+	LastStateRootIndex uint32 `json:"lastStateRootIndex"`
+}
+
+// Checkpointer persists relay progress so a restart resumes at
+// max(cfg.Start, cursor+1) instead of replaying from cfg.Start and relying
+// on the contract's "already synced"/"already minted" errors to skip work
+// it already did. It also durably tracks the multi-signer Coordinator's
+// local nonce: unlike Cursor, which is only saved once a whole batch is
+// mined, SaveCoordinatorNonce is called the moment a nonce is handed out,
+// so a crash can never cause a nonce to be reused against the bridge's
+// replay protection.
+type Checkpointer interface {
+	Load() (*Cursor, error)
+	Save(*Cursor) error
+	LoadCoordinatorNonce() (uint64, error)
+	SaveCoordinatorNonce(uint64) error
+}
+
+// boltCheckpointer is the default Checkpointer, backed by a local BoltDB
+// file so a single relayer process survives a restart without needing an
+// external store.
+type boltCheckpointer struct {
+	db *bolt.DB
+}
+
+// NewBoltCheckpointer opens (creating if necessary) a BoltDB file at path
+// to use as the relayer's checkpoint store.
+func NewBoltCheckpointer(path string) (Checkpointer, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("can't open checkpoint db: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(checkpointBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(coordinatorBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("can't init checkpoint bucket: %w", err)
+	}
+	return &boltCheckpointer{db: db}, nil
+}
+
+func (c *boltCheckpointer) Load() (*Cursor, error) {
+	var cursor *Cursor
+	err := c.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(checkpointBucket).Get(cursorKey)
+		if v == nil {
+			return nil
+		}
+		cursor = new(Cursor)
+		return json.Unmarshal(v, cursor)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't load cursor: %w", err)
+	}
+	return cursor, nil
+}
+
+func (c *boltCheckpointer) Save(cursor *Cursor) error {
+	v, err := json.Marshal(cursor)
+	if err != nil {
+		return fmt.Errorf("can't marshal cursor: %w", err)
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(checkpointBucket).Put(cursorKey, v)
+	})
+}
+
+func (c *boltCheckpointer) LoadCoordinatorNonce() (uint64, error) {
+	var nonce uint64
+	err := c.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(coordinatorBucket).Get(nonceKey)
+		if v == nil {
+			return nil
+		}
+		nonce = binary.BigEndian.Uint64(v)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("can't load coordinator nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+func (c *boltCheckpointer) SaveCoordinatorNonce(nonce uint64) error {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, nonce)
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(coordinatorBucket).Put(nonceKey, v)
+	})
+}
@@ -0,0 +1,94 @@
+package relay
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// ParseError carries everything needed to investigate a malformed
+// contract event after the fact: which tx and event emitted it, the raw
+// stack item the parser choked on, and the underlying parse failure.
+type ParseError struct {
+	TxHash     util.Uint256 `json:"txHash"`
+	EventIndex int          `json:"eventIndex"`
+	RawItem    string       `json:"rawItem"`
+	Err        error        `json:"-"`
+	Message    string       `json:"error"`
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse error, tx=%s, event=%d: %s", e.TxHash, e.EventIndex, e.Message)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// DeadLetterSink is where events that fail to parse are routed instead of
+// crashing the relayer. The default appends them to a JSONL file so an
+// operator can replay or inspect them later; tests and alternate
+// deployments can swap in their own.
+type DeadLetterSink interface {
+	Record(*ParseError) error
+}
+
+// jsonlDeadLetterSink appends one JSON object per line to a file under
+// the relayer's data dir.
+type jsonlDeadLetterSink struct {
+	path string
+}
+
+// NewJSONLDeadLetterSink returns a DeadLetterSink that appends to the
+// JSONL file at path, creating it if necessary.
+func NewJSONLDeadLetterSink(path string) DeadLetterSink {
+	return &jsonlDeadLetterSink{path: path}
+}
+
+func (s *jsonlDeadLetterSink) Record(pe *ParseError) error {
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("can't open dead letter file: %w", err)
+	}
+	defer f.Close()
+	b, err := json.Marshal(pe)
+	if err != nil {
+		return fmt.Errorf("can't marshal parse error: %w", err)
+	}
+	b = append(b, '\n')
+	_, err = f.Write(b)
+	return err
+}
+
+// deadLetterCount is how many events have been dead-lettered since the
+// process started. Exposed via DeadLetterCount for operators to
+// wire into their own metrics exporter.
+var deadLetterCount uint64
+
+// DeadLetterCount returns how many events this process has dead-lettered.
+func DeadLetterCount() uint64 {
+	return atomic.LoadUint64(&deadLetterCount)
+}
+
+// deadLetter records a parse failure instead of letting it crash the
+// relayer: a single weird contract event should never take down the
+// whole process, only RPC connectivity loss and signing failures should.
+func (l *Relayer) deadLetter(txHash util.Uint256, eventIndex int, event *state.NotificationEvent, cause error) {
+	pe := &ParseError{
+		TxHash:     txHash,
+		EventIndex: eventIndex,
+		RawItem:    fmt.Sprintf("%v", event.Item),
+		Err:        cause,
+		Message:    cause.Error(),
+	}
+	atomic.AddUint64(&deadLetterCount, 1)
+	log.Printf("dead-lettering unparseable event, tx=%s, event=%d: %v", txHash, eventIndex, cause)
+	if err := l.deadLetters.Record(pe); err != nil {
+		log.Printf("can't record dead letter, tx=%s, event=%d: %v", txHash, eventIndex, err)
+	}
+}
@@ -0,0 +1,102 @@
+package relay
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ZhangTao1596/neo-evm-bridge/config"
+	"github.com/ZhangTao1596/neo-evm-bridge/constantclient"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// gasBumpPercent is the flat bump applied to a stuck tx's fee before
+// resubmission: enough to clear most fee-market congestion without an
+// operator needing to intervene.
+const gasBumpPercent = 20
+
+// defaultPriorityFeeGwei is used when cfg.PriorityFeeWei isn't set.
+const defaultPriorityFeeGwei = 2
+
+// FeeParams carries the fee fields needed to build an Ethereum tx, covering
+// both the legacy single gas price model and EIP-1559's tip/fee cap model.
+type FeeParams struct {
+	Legacy    bool
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// FeeStrategy decides what fee a relayer tx should carry and how to raise
+// it on resubmission when a previous attempt wasn't mined in time. Side
+// chains vary in whether they support EIP-1559, so this is pluggable
+// instead of hard-coded to one tx type.
+type FeeStrategy interface {
+	Prepare() (*FeeParams, error)
+	Bump(prior *FeeParams) *FeeParams
+}
+
+// newFeeStrategy picks a FeeStrategy for the side chain. cfg.DynamicFees
+// forces EIP-1559 mode; otherwise it probes eth_feeHistory and falls back
+// to the legacy strategy if the endpoint doesn't support it.
+func newFeeStrategy(cfg *config.Config, client *constantclient.ConstantClient) FeeStrategy {
+	tip := big.NewInt(defaultPriorityFeeGwei * 1_000_000_000)
+	if cfg.PriorityFeeWei != nil {
+		tip = cfg.PriorityFeeWei
+	}
+	if cfg.DynamicFees || client.Eth_SupportsFeeHistory() {
+		return &dynamicFeeStrategy{client: client, tipWei: tip}
+	}
+	return &legacyFeeStrategy{client: client}
+}
+
+// legacyFeeStrategy targets EVM side chains that don't support EIP-1559;
+// it mirrors the relayer's original behavior of just reading Eth_GasPrice.
+type legacyFeeStrategy struct {
+	client *constantclient.ConstantClient
+}
+
+func (s *legacyFeeStrategy) Prepare() (*FeeParams, error) {
+	return &FeeParams{Legacy: true, GasPrice: s.client.Eth_GasPrice()}, nil
+}
+
+func (s *legacyFeeStrategy) Bump(prior *FeeParams) *FeeParams {
+	return &FeeParams{Legacy: true, GasPrice: bumpByPercent(prior.GasPrice)}
+}
+
+// dynamicFeeStrategy targets EIP-1559 side chains: it sets GasFeeCap to
+// twice the most recent base fee plus a configurable priority tip.
+type dynamicFeeStrategy struct {
+	client *constantclient.ConstantClient
+	tipWei *big.Int
+}
+
+func (s *dynamicFeeStrategy) Prepare() (*FeeParams, error) {
+	baseFee, err := s.client.Eth_FeeHistory(1)
+	if err != nil {
+		return nil, fmt.Errorf("can't read fee history: %w", err)
+	}
+	feeCap := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), s.tipWei)
+	return &FeeParams{GasTipCap: s.tipWei, GasFeeCap: feeCap}, nil
+}
+
+func (s *dynamicFeeStrategy) Bump(prior *FeeParams) *FeeParams {
+	return &FeeParams{
+		GasTipCap: bumpByPercent(prior.GasTipCap),
+		GasFeeCap: bumpByPercent(prior.GasFeeCap),
+	}
+}
+
+func bumpByPercent(v *big.Int) *big.Int {
+	b := new(big.Int).Mul(v, big.NewInt(100+gasBumpPercent))
+	return b.Div(b, big.NewInt(100))
+}
+
+// feeParamsFromTx recovers the FeeParams a previously built tx used, so a
+// resubmission can hand them to FeeStrategy.Bump without the caller having
+// to thread FeeParams alongside every in-flight *types.Transaction.
+func feeParamsFromTx(tx *types.Transaction) *FeeParams {
+	if tx.Type() == types.DynamicFeeTxType {
+		return &FeeParams{GasTipCap: tx.GasTipCap(), GasFeeCap: tx.GasFeeCap()}
+	}
+	return &FeeParams{Legacy: true, GasPrice: tx.GasPrice()}
+}
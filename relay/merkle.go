@@ -0,0 +1,89 @@
+package relay
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// txMerkle is the full transaction merkle tree of a Neo block, built once
+// per taskBatch in sync() instead of recomputing it for every task in the
+// batch. For a batch with k tasks in an N-tx
+// block this drops proof generation from O(k*N) to O(N + k*log N).
+type txMerkle struct {
+	layers [][]util.Uint256
+	index  map[util.Uint256]int
+}
+
+// newTxMerkle computes every layer of the pairwise SHA256-SHA256 merkle
+// tree over txHashes, duplicating the last hash of a layer when it has an
+// odd number of nodes -- the same construction neo-go uses to derive
+// block.Header.MerkleRoot.
+func newTxMerkle(txHashes []util.Uint256) *txMerkle {
+	m := &txMerkle{index: make(map[util.Uint256]int, len(txHashes))}
+	leaves := append([]util.Uint256{}, txHashes...)
+	for i, h := range leaves {
+		m.index[h] = i
+	}
+	m.layers = append(m.layers, leaves)
+	for layer := leaves; len(layer) > 1; {
+		if len(layer)%2 != 0 {
+			layer = append(layer, layer[len(layer)-1])
+		}
+		next := make([]util.Uint256, len(layer)/2)
+		for i := range next {
+			next[i] = hashPair(layer[2*i], layer[2*i+1])
+		}
+		m.layers = append(m.layers, next)
+		layer = next
+	}
+	return m
+}
+
+func hashPair(a, b util.Uint256) util.Uint256 {
+	buf := make([]byte, 0, util.Uint256Size*2)
+	buf = append(buf, a.BytesBE()...)
+	buf = append(buf, b.BytesBE()...)
+	first := sha256.Sum256(buf)
+	second := sha256.Sum256(first[:])
+	h, _ := util.Uint256DecodeBytesBE(second[:])
+	return h
+}
+
+// root returns the computed merkle root, which should match the owning
+// block's Header.MerkleRoot.
+func (m *txMerkle) root() util.Uint256 {
+	top := m.layers[len(m.layers)-1]
+	return top[0]
+}
+
+// proof walks from txid's leaf up to the root, returning the sibling hash
+// at every level -- enough for a verifier to recompute the root and check
+// it against the block header's MerkleRoot.
+func (m *txMerkle) proof(txid util.Uint256) ([]util.Uint256, error) {
+	idx, ok := m.index[txid]
+	if !ok {
+		return nil, fmt.Errorf("tx %s is not in this block", txid)
+	}
+	proof := make([]util.Uint256, 0, len(m.layers)-1)
+	for _, layer := range m.layers[:len(m.layers)-1] {
+		siblingIdx := idx ^ 1
+		if siblingIdx >= len(layer) {
+			siblingIdx = idx
+		}
+		proof = append(proof, layer[siblingIdx])
+		idx /= 2
+	}
+	return proof, nil
+}
+
+// proofBytes serializes a proof as the concatenation of its sibling hashes,
+// sized for the contract-side verifier to walk back up from a leaf index.
+func proofBytes(proof []util.Uint256) []byte {
+	b := make([]byte, 0, len(proof)*util.Uint256Size)
+	for _, h := range proof {
+		b = append(b, h.BytesBE()...)
+	}
+	return b
+}
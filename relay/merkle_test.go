@@ -0,0 +1,92 @@
+package relay
+
+import (
+	"crypto/rand"
+	mathrand "math/rand"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+func randomUint256(t *testing.T) util.Uint256 {
+	t.Helper()
+	var b [util.Uint256Size]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		t.Fatalf("can't read random bytes: %v", err)
+	}
+	return util.Uint256(b)
+}
+
+// verifyProof recomputes the root by walking up from leaf using the
+// sibling hashes in proof and txMerkle's own hashPair, the same way an
+// on-chain verifier would. This checks proof() against this package's
+// root, not neo-go's -- TestTxMerkleRootMatchesNeoGo below is what
+// checks hashPair itself against neo-go's real implementation, so a bug
+// in hashPair can't hide behind the two tests only ever agreeing with
+// each other.
+func verifyProof(leaf util.Uint256, index int, proof []util.Uint256) util.Uint256 {
+	h := leaf
+	for _, sibling := range proof {
+		if index%2 == 0 {
+			h = hashPair(h, sibling)
+		} else {
+			h = hashPair(sibling, h)
+		}
+		index /= 2
+	}
+	return h
+}
+
+func TestTxMerkleProofMatchesRoot(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 15, 16, 33} {
+		hashes := make([]util.Uint256, n)
+		for i := range hashes {
+			hashes[i] = randomUint256(t)
+		}
+		m := newTxMerkle(hashes)
+		for i, h := range hashes {
+			proof, err := m.proof(h)
+			if err != nil {
+				t.Fatalf("n=%d: proof(%d): %v", n, i, err)
+			}
+			if got := verifyProof(h, i, proof); got != m.root() {
+				t.Fatalf("n=%d, leaf=%d: recomputed root %s != tree root %s", n, i, got, m.root())
+			}
+		}
+	}
+}
+
+func TestTxMerkleProofUnknownTx(t *testing.T) {
+	m := newTxMerkle([]util.Uint256{randomUint256(t)})
+	if _, err := m.proof(randomUint256(t)); err == nil {
+		t.Fatal("expected an error for a tx hash not in the tree")
+	}
+}
+
+// TestTxMerkleRootMatchesNeoGo is the genuine cross-check: it builds the
+// same set of tx hashes through neo-go's own hash.NewMerkleTree -- the
+// code neo-go itself uses to derive block.Header.MerkleRoot -- and
+// checks txMerkle agrees with it. Unlike a hand-copied reimplementation
+// of the pairwise SHA256-SHA256 formula, this catches a mistake in
+// hashPair (wrong byte order, wrong hash composition, wrong
+// duplicate-on-odd-layer rule) relative to the real protocol, not just
+// relative to itself.
+func TestTxMerkleRootMatchesNeoGo(t *testing.T) {
+	r := mathrand.New(mathrand.NewSource(1))
+	for trial := 0; trial < 20; trial++ {
+		n := 1 + r.Intn(40)
+		hashes := make([]util.Uint256, n)
+		for i := range hashes {
+			hashes[i] = randomUint256(t)
+		}
+		want, err := hash.NewMerkleTree(hashes)
+		if err != nil {
+			t.Fatalf("trial %d, n=%d: neo-go NewMerkleTree: %v", trial, n, err)
+		}
+		m := newTxMerkle(hashes)
+		if got := m.root(); got != want.Root() {
+			t.Fatalf("trial %d, n=%d: tree root %s != neo-go root %s", trial, n, got, want.Root())
+		}
+	}
+}
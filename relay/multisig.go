@@ -0,0 +1,366 @@
+package relay
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ZhangTao1596/neo-evm-bridge/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/neo-ngd/neo-go/pkg/wallet"
+)
+
+const (
+	CCMSyncHeaderMulti  = "syncHeaderMulti"
+	CCMRequestMintMulti = "requestMintMulti"
+)
+
+// multiSigMethod reports the threshold-signed entry point a single-key
+// method should be submitted through when multi-signer coordination is
+// enabled. Not every sync method has one: state root and validator
+// syncs are comparatively low-stakes and cheap to resync, so only the
+// two operations that actually move value or trust (header sync, which
+// gates state proofs, and mint) go through the threshold path.
+func multiSigMethod(method string) (string, bool) {
+	switch method {
+	case CCMSyncHeader:
+		return CCMSyncHeaderMulti, true
+	case CCMRequestMint:
+		return CCMRequestMintMulti, true
+	default:
+		return "", false
+	}
+}
+
+// PeerSignature is one relayer's ECDSA signature over a SignedSyncPayload's
+// canonical hash, tagged with which relayer produced it and which payload
+// hash the signature is over. Carrying PayloadHash lets a receiver (and
+// Collect, once a signature reaches it) tell a signature for this round
+// apart from one produced for a different block or a concurrent round,
+// instead of trusting whichever signature happens to arrive next.
+type PeerSignature struct {
+	RelayerIndex int    `json:"relayerIndex"`
+	PayloadHash  []byte `json:"payloadHash"`
+	Signature    []byte `json:"signature"`
+}
+
+// SignedSyncPayload is what an M-of-N relayer set gossips and eventually
+// submits together: the already ABI-packed calldata a single relayer
+// would have submitted alone (header/stateroot/proof, with its method
+// selector) plus enough signatures to clear the bridge contract's
+// threshold check.
+type SignedSyncPayload struct {
+	Method     string          `json:"method"`
+	NeoBlock   uint32          `json:"neoBlock"`
+	Nonce      uint64          `json:"nonce"`
+	Object     []byte          `json:"object"`
+	Signatures []PeerSignature `json:"signatures"`
+}
+
+// hash returns the canonical payload hash every relayer signs. Including
+// the Neo block index and a per-relayer nonce means a captured signature
+// can't be replayed against a different block or resubmitted twice.
+func (p *SignedSyncPayload) hash() []byte {
+	b, _ := json.Marshal(struct {
+		Method   string `json:"method"`
+		NeoBlock uint32 `json:"neoBlock"`
+		Nonce    uint64 `json:"nonce"`
+		Object   []byte `json:"object"`
+	}{p.Method, p.NeoBlock, p.Nonce, p.Object})
+	return crypto.Keccak256(b)
+}
+
+// PeerTransport is the gossip mesh M-of-N relayers use to exchange
+// signatures. The bridge doesn't care how a signature reaches the round's
+// leader, so this stays swappable between the plain TCP mesh below and a
+// libp2p pubsub mesh for deployments that need NAT traversal or peer
+// discovery.
+type PeerTransport interface {
+	Broadcast(sig PeerSignature) error
+	Signatures() <-chan PeerSignature
+}
+
+// maxGossipFrame bounds how large an inbound length-prefixed frame
+// gossipTransport will read, so a misbehaving peer can't make it buffer
+// an unbounded amount of memory before json.Unmarshal ever runs.
+const maxGossipFrame = 1 << 20
+
+// gossipTransport is the default PeerTransport: every relayer dials every
+// peer directly and pushes its signature as a length-delimited JSON
+// frame, and listens for the same from its peers. It assumes a small,
+// operator-configured peer set (cfg.Peers/cfg.PeerPublicKeys) rather than
+// discovery, which matches how this bridge's relayer fleet is expected to
+// be run.
+//
+// Authentication doesn't happen at the TCP layer -- it doesn't need to.
+// Every inbound frame's signature is verified against the public key the
+// operator configured for its claimed RelayerIndex before it's ever
+// handed to Signatures(), so a peer can't get a bogus signature counted
+// just by dialing in and asserting an index it doesn't hold the key for.
+type gossipTransport struct {
+	addrs    map[int]string
+	peerKeys map[int]*ecdsa.PublicKey
+	in       chan PeerSignature
+}
+
+// newGossipTransport starts listening on listenAddr for inbound peer
+// signatures and returns a transport that dials addrs (keyed by relayer
+// index) to broadcast this relayer's own. peerKeys is used to authenticate
+// every inbound signature against the public key on file for the relayer
+// index it claims to be from.
+func newGossipTransport(listenAddr string, addrs map[int]string, peerKeys map[int]*ecdsa.PublicKey) (*gossipTransport, error) {
+	ln, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("can't listen for peer signatures on %s: %w", listenAddr, err)
+	}
+	t := &gossipTransport{addrs: addrs, peerKeys: peerKeys, in: make(chan PeerSignature, 64)}
+	go t.accept(ln)
+	return t, nil
+}
+
+func (t *gossipTransport) accept(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			log.Printf("gossip transport: listener on %s stopped: %v", ln.Addr(), err)
+			return
+		}
+		go t.handle(conn)
+	}
+}
+
+func (t *gossipTransport) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	sizeLine, err := r.ReadString('\n')
+	if err != nil {
+		log.Printf("gossip transport: dropping connection with malformed frame header: %v", err)
+		return
+	}
+	size, err := strconv.Atoi(strings.TrimSpace(sizeLine))
+	if err != nil || size <= 0 || size > maxGossipFrame {
+		log.Printf("gossip transport: dropping connection with implausible frame size %q", sizeLine)
+		return
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		log.Printf("gossip transport: dropping connection, short frame: %v", err)
+		return
+	}
+	var sig PeerSignature
+	if err := json.Unmarshal(frame, &sig); err != nil {
+		log.Printf("gossip transport: dropping malformed signature frame: %v", err)
+		return
+	}
+	pub, ok := t.peerKeys[sig.RelayerIndex]
+	if !ok {
+		log.Printf("gossip transport: dropping signature from unknown relayer index %d", sig.RelayerIndex)
+		return
+	}
+	recovered, err := crypto.SigToPub(sig.PayloadHash, sig.Signature)
+	if err != nil || !recovered.Equal(pub) {
+		log.Printf("gossip transport: dropping signature that doesn't verify against relayer %d's public key", sig.RelayerIndex)
+		return
+	}
+	t.in <- sig
+}
+
+func (t *gossipTransport) Broadcast(sig PeerSignature) error {
+	b, err := json.Marshal(sig)
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	for idx, addr := range t.addrs {
+		if err := t.send(addr, b); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("can't reach peer %d (%s): %w", idx, addr, err)
+		}
+	}
+	return firstErr
+}
+
+func (t *gossipTransport) send(addr string, frame []byte) error {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	w := bufio.NewWriter(conn)
+	if _, err := fmt.Fprintf(w, "%d\n", len(frame)); err != nil {
+		return err
+	}
+	if _, err := w.Write(frame); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func (t *gossipTransport) Signatures() <-chan PeerSignature {
+	return t.in
+}
+
+// Coordinator runs the M-of-N signing protocol for one relayer instance:
+// it signs every sync payload locally, gossips the signature, and — on
+// the round whose turn it is, chosen round-robin so no single relayer is
+// a submission bottleneck — collects threshold signatures and submits a
+// single aggregated tx instead of every relayer submitting its own.
+type Coordinator struct {
+	index       int
+	total       int
+	threshold   int
+	key         *ecdsa.PrivateKey
+	transport   PeerTransport
+	nonces      Checkpointer
+	mu          sync.Mutex
+	localNonce  uint64
+	seenByRound map[string]map[int]bool
+}
+
+// NewCoordinator builds a Coordinator for relayer `index` out of `total`
+// known relayers, requiring `threshold` signatures (including its own)
+// before a payload is submitted. nonces durably tracks the next signing
+// nonce to hand out, so a restart can't reuse a nonce a prior process
+// already signed with.
+func NewCoordinator(index, total, threshold int, key *ecdsa.PrivateKey, nonces Checkpointer, transport PeerTransport) (*Coordinator, error) {
+	if threshold < 1 || threshold > total {
+		return nil, fmt.Errorf("invalid threshold %d of %d relayers", threshold, total)
+	}
+	if index < 0 || index >= total {
+		return nil, fmt.Errorf("relayer index %d out of range [0,%d)", index, total)
+	}
+	localNonce, err := nonces.LoadCoordinatorNonce()
+	if err != nil {
+		return nil, fmt.Errorf("can't load coordinator nonce: %w", err)
+	}
+	return &Coordinator{
+		index:       index,
+		total:       total,
+		threshold:   threshold,
+		key:         key,
+		transport:   transport,
+		nonces:      nonces,
+		localNonce:  localNonce,
+		seenByRound: make(map[string]map[int]bool),
+	}, nil
+}
+
+// isLeader reports whether this relayer instance aggregates signatures
+// and submits the tx for neoBlock.
+func (c *Coordinator) isLeader(neoBlock uint32) bool {
+	return int(neoBlock)%c.total == c.index
+}
+
+// nextNonce hands out the next signing nonce, persisting it before
+// returning so a crash between allocation and use can never cause the
+// same nonce to be signed twice.
+func (c *Coordinator) nextNonce() (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.localNonce++
+	if err := c.nonces.SaveCoordinatorNonce(c.localNonce); err != nil {
+		c.localNonce--
+		return 0, fmt.Errorf("can't persist coordinator nonce: %w", err)
+	}
+	return c.localNonce, nil
+}
+
+// Collect signs payload, gossips the signature to every peer, and — only
+// on the instance whose turn it is for payload.NeoBlock — blocks until
+// threshold signatures (including its own) have been gathered, returning
+// the aggregated payload ready to submit. Non-leader instances return a
+// nil payload and nil error once they've broadcast their own signature.
+//
+// Every signature arriving over c.transport has already been verified
+// against its claimed relayer's public key before it ever reaches the
+// channel Collect reads from (see gossipTransport.handle); Collect only
+// has to check that it's a signature for *this* round rather than a
+// stale one left over from a different block or a concurrent round.
+func (c *Coordinator) Collect(payload *SignedSyncPayload) (*SignedSyncPayload, error) {
+	hash := payload.hash()
+	sig, err := crypto.Sign(hash, c.key)
+	if err != nil {
+		return nil, fmt.Errorf("can't sign sync payload: %w", err)
+	}
+	mine := PeerSignature{RelayerIndex: c.index, PayloadHash: hash, Signature: sig}
+	if err := c.transport.Broadcast(mine); err != nil {
+		return nil, fmt.Errorf("can't broadcast signature: %w", err)
+	}
+	payload.Signatures = append(payload.Signatures, mine)
+	if !c.isLeader(payload.NeoBlock) {
+		return nil, nil
+	}
+	for len(payload.Signatures) < c.threshold {
+		sig := <-c.transport.Signatures()
+		if !bytes.Equal(sig.PayloadHash, hash) {
+			log.Printf("ignoring signature from relayer %d for a different round", sig.RelayerIndex)
+			continue
+		}
+		if c.alreadyCounted(hash, sig.RelayerIndex) {
+			continue
+		}
+		payload.Signatures = append(payload.Signatures, sig)
+	}
+	log.Printf("collected %d/%d signatures for neo block %d", len(payload.Signatures), c.threshold, payload.NeoBlock)
+	return payload, nil
+}
+
+// newMultiSigCoordinator builds the Coordinator and gossip transport for
+// cfg's configured peer set. cfg.Peers lists every other relayer's gossip
+// address in ascending relayer-index order, skipping this relayer's own
+// index; cfg.PeerPublicKeys carries one hex-encoded uncompressed public
+// key per entry in cfg.Peers, in the same order, so gossipTransport can
+// authenticate the signatures it receives from each of them.
+func newMultiSigCoordinator(cfg *config.Config, acc *wallet.Account, nonces Checkpointer) (*Coordinator, error) {
+	total := len(cfg.Peers) + 1
+	if len(cfg.PeerPublicKeys) != len(cfg.Peers) {
+		return nil, fmt.Errorf("cfg.PeerPublicKeys must list one key per entry in cfg.Peers (%d peers, %d keys)", len(cfg.Peers), len(cfg.PeerPublicKeys))
+	}
+	addrs := make(map[int]string, len(cfg.Peers))
+	peerKeys := make(map[int]*ecdsa.PublicKey, len(cfg.Peers))
+	peerIdx := 0
+	for i := 0; i < total; i++ {
+		if i == cfg.RelayerIndex {
+			continue
+		}
+		pub, err := crypto.UnmarshalPubkey(common.FromHex(cfg.PeerPublicKeys[peerIdx]))
+		if err != nil {
+			return nil, fmt.Errorf("can't parse public key for relayer %d: %w", i, err)
+		}
+		addrs[i] = cfg.Peers[peerIdx]
+		peerKeys[i] = pub
+		peerIdx++
+	}
+	transport, err := newGossipTransport(cfg.ListenAddr, addrs, peerKeys)
+	if err != nil {
+		return nil, fmt.Errorf("can't set up peer signature transport: %w", err)
+	}
+	return NewCoordinator(cfg.RelayerIndex, total, cfg.Threshold, &acc.PrivateKey().PrivateKey, nonces, transport)
+}
+
+// alreadyCounted tracks which relayer indices have contributed a
+// signature for a given payload hash, so a duplicate or replayed broadcast
+// can't be counted twice toward the threshold, while a block that needs
+// several payloads (a header sync and a mint) still collects each one.
+func (c *Coordinator) alreadyCounted(payloadHash []byte, relayerIndex int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	seen, ok := c.seenByRound[string(payloadHash)]
+	if !ok {
+		seen = make(map[int]bool)
+		c.seenByRound[string(payloadHash)] = seen
+	}
+	if seen[relayerIndex] {
+		return true
+	}
+	seen[relayerIndex] = true
+	return false
+}
@@ -0,0 +1,158 @@
+package relay
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+)
+
+const (
+	// fetchAhead bounds how many blocks the fetch stage may pull ahead of
+	// the commit stage, so a slow Ethereum side chain can't make the
+	// relayer buffer an unbounded number of Neo blocks in memory.
+	fetchAhead = 8
+	// fetchWorkers caps concurrent GetApplicationLog fetches when polling.
+	fetchWorkers = 4
+)
+
+// fetchedBlock is what the fetch stage hands to the analyze stage: a block
+// together with the notifications relevant to it, whether they arrived by
+// websocket push or by walking application logs.
+type fetchedBlock struct {
+	block         *block.Block
+	notifications []state.ContainedNotificationEvent
+}
+
+// runPipeline replaces the old strictly serial fetch -> analyze -> commit
+// loop with three stages connected by bounded channels: fetch pulls blocks
+// (and their application logs, when polling) ahead of the committer,
+// analyze turns them into taskBatches, and commit signs and submits
+// Ethereum txs. Overlapping these means GetApplicationLog calls for block
+// N+1 happen while block N is still waiting on Ethereum inclusion.
+func (l *Relayer) runPipeline(sub *subscription) {
+	fetched := make(chan fetchedBlock, fetchAhead)
+	batches := make(chan *taskBatch, fetchAhead)
+	go l.fetchStage(sub, fetched)
+	go l.analyzeStage(fetched, batches)
+	l.commitStage(batches)
+}
+
+// fetchStage prefers the websocket subscription when one is available and
+// falls back to polling ahead with a worker pool if the subscription was
+// never established or its stream closes mid-run.
+//
+// A subscription only streams blocks produced after it was opened, so if
+// startIndex is behind the chain tip at the time subscribe() ran, the
+// blocks in between would never arrive on sub.events. fetchStage closes
+// that gap by polling up to the tip first and only then switching to the
+// live stream, instead of silently discarding every subscribed block
+// whose index doesn't match next forever.
+func (l *Relayer) fetchStage(sub *subscription, out chan<- fetchedBlock) {
+	defer close(out)
+	next := l.startIndex
+	if sub != nil {
+		defer sub.close()
+		if tip := l.client.GetBlockCount(); next < tip {
+			upto := tip
+			if l.cfg.End < upto {
+				upto = l.cfg.End
+			}
+			log.Printf("catching up to chain tip, polling %d..%d before switching to the live subscription", next, upto)
+			l.pollAhead(next, upto, out)
+			next = upto
+		}
+		if next >= l.cfg.End {
+			return
+		}
+		for evt := range sub.events {
+			idx := uint32(evt.block.Index)
+			if idx < next {
+				continue
+			}
+			if idx >= l.cfg.End {
+				return
+			}
+			if idx != next {
+				continue
+			}
+			out <- fetchedBlock{block: evt.block, notifications: evt.notifications}
+			next++
+		}
+		if next >= l.cfg.End {
+			return
+		}
+		log.Println("subscription closed, falling back to polling")
+	}
+	l.pollAhead(next, l.cfg.End, out)
+}
+
+// pollAhead fetches blocks (and their application logs) in [from, to), up
+// to fetchWorkers at a time, and emits them to out strictly in order.
+func (l *Relayer) pollAhead(from, to uint32, out chan<- fetchedBlock) {
+	type result struct {
+		index uint32
+		fb    fetchedBlock
+	}
+	results := make(chan result, fetchAhead)
+	sem := make(chan struct{}, fetchWorkers)
+	next, inFlight, emit := from, uint32(0), from
+	pending := map[uint32]fetchedBlock{}
+	for emit < to {
+		for inFlight < fetchAhead && next < to {
+			idx := next
+			next++
+			inFlight++
+			sem <- struct{}{}
+			go func() {
+				defer func() { <-sem }()
+				log.Printf("fetching block, index=%d", idx)
+				b := l.client.GetBlock(idx)
+				for b == nil {
+					time.Sleep(15 * time.Second)
+					b = l.client.GetBlock(idx)
+				}
+				results <- result{index: idx, fb: fetchedBlock{block: b, notifications: l.notificationsFromLog(b)}}
+			}()
+		}
+		r := <-results
+		inFlight--
+		pending[r.index] = r.fb
+		for {
+			fb, ok := pending[emit]
+			if !ok {
+				break
+			}
+			out <- fb
+			delete(pending, emit)
+			emit++
+		}
+	}
+}
+
+// analyzeStage turns fetched blocks into taskBatches, one goroutine ahead
+// of the committer so batch construction never blocks on tx inclusion.
+func (l *Relayer) analyzeStage(in <-chan fetchedBlock, out chan<- *taskBatch) {
+	defer close(out)
+	for fb := range in {
+		out <- l.buildBatch(fb.block, fb.notifications)
+	}
+}
+
+// commitStage signs and submits every batch in order. It is the only
+// stage that touches account nonces, waits on Ethereum inclusion, or
+// reads/writes l.lastHeader -- isJoint is computed here rather than in
+// the analyze stage specifically so the analyze stage, which may run
+// fetchAhead blocks ahead of commit, never races commitStage's write to
+// l.lastHeader below.
+func (l *Relayer) commitStage(in <-chan *taskBatch) {
+	for batch := range in {
+		batch.isJoint = l.isJointHeader(&batch.block.Header)
+		if err := l.sync(batch); err != nil {
+			panic(fmt.Errorf("can't sync block %d: %w", batch.Index(), err))
+		}
+		l.lastHeader = &batch.block.Header
+	}
+}
@@ -3,11 +3,14 @@ package relay
 import (
 	"crypto/elliptic"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"math/big"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ZhangTao1596/neo-evm-bridge/config"
@@ -56,6 +59,14 @@ type Relayer struct {
 	client                        *constantclient.ConstantClient
 	bridge                        *sstate.NativeContract
 	account                       *wallet.Account
+	checkpoint                    Checkpointer
+	startIndex                    uint32
+	nonceMu                       sync.Mutex
+	nonce                         uint64
+	nonceLoaded                   bool
+	fees                          FeeStrategy
+	coordinator                   *Coordinator
+	deadLetters                   DeadLetterSink
 }
 
 func NewRelayer(cfg *config.Config, acc *wallet.Account) (*Relayer, error) {
@@ -68,82 +79,145 @@ func NewRelayer(cfg *config.Config, acc *wallet.Account) (*Relayer, error) {
 	if bridge == nil {
 		return nil, errors.New("can't get bridge contract")
 	}
+	checkpoint, err := NewBoltCheckpointer(filepath.Join(cfg.DataDir, "checkpoint.db"))
+	if err != nil {
+		return nil, fmt.Errorf("can't open checkpoint store: %w", err)
+	}
+	startIndex := cfg.Start
+	cursor, err := checkpoint.Load()
+	if err != nil {
+		return nil, err
+	}
+	var lastStateRoot *state.MPTRoot
+	if cursor != nil {
+		if cursor.LastBlockIndex+1 > startIndex {
+			startIndex = cursor.LastBlockIndex + 1
+		}
+		if cursor.LastStateRootIndex > 0 {
+			lastStateRoot = client.GetStateRoot(cursor.LastStateRootIndex)
+		}
+	}
+	var coordinator *Coordinator
+	if len(cfg.Peers) > 0 {
+		coordinator, err = newMultiSigCoordinator(cfg, acc, checkpoint)
+		if err != nil {
+			return nil, fmt.Errorf("can't set up multi-signer coordination: %w", err)
+		}
+	}
 	return &Relayer{
 		cfg:                           cfg,
+		lastStateRoot:                 lastStateRoot,
 		roleManagementContractAddress: roleManagement,
 		client:                        client,
 		bridge:                        bridge,
 		account:                       acc,
+		checkpoint:                    checkpoint,
+		startIndex:                    startIndex,
+		fees:                          newFeeStrategy(cfg, client),
+		coordinator:                   coordinator,
+		deadLetters:                   NewJSONLDeadLetterSink(filepath.Join(cfg.DataDir, "dead-letters.jsonl")),
 	}, nil
 }
 
+// SetFeeStrategy overrides the fee strategy newFeeStrategy picked in
+// NewRelayer. Most deployments don't need this -- cfg.DynamicFees and the
+// eth_feeHistory probe cover the common cases -- but it gives operators
+// and tests a way to supply a FeeStrategy of their own instead of being
+// stuck with whatever newFeeStrategy's auto-detection decided.
+func (l *Relayer) SetFeeStrategy(fees FeeStrategy) {
+	l.fees = fees
+}
+
 func (l *Relayer) Run() {
-	for i := l.cfg.Start; i < l.cfg.End; {
-		log.Printf("syncing block, index=%d", i)
-		block := l.client.GetBlock(i)
-		if block == nil {
-			time.Sleep(15 * time.Second)
-			continue
+	var sub *subscription
+	if l.cfg.UseSubscription {
+		s, err := subscribe(l.cfg.MainSeeds, l.cfg.BridgeContract, l.roleManagementContractAddress)
+		if err != nil {
+			log.Printf("can't subscribe, falling back to polling: %v", err)
+		} else {
+			log.Println("subscribed to block_added/notification_from_execution, polling disabled")
+			sub = s
 		}
-		batch := new(taskBatch)
-		batch.block = block
-		batch.isJoint = l.isJointHeader(&block.Header)
-		for _, tx := range block.Transactions {
-			log.Printf("syncing tx, hash=%s\n", tx.Hash())
-			applicationlog := l.client.GetApplicationLog(tx.Hash())
-			for _, execution := range applicationlog.Executions {
-				if execution.Trigger == trigger.Application && execution.VMState == vmstate.Halt {
-					for _, nevent := range execution.Events {
-						event := &nevent
-						if l.isManageContract(event) {
-							if isDepositEvent(event) {
-								requestId, from, amount, to, err := l.parseDepositEvent(event)
-								if err != nil {
-									panic(err)
-								}
-								log.Printf("deposit event, id=%d, from=%s, amount=%d, to=%s\n", requestId, from, amount, to)
-								if amount < MintThreshold {
-									log.Printf("threshold unreached, id=%d, from=%s, amount=%d, to=%s\n", requestId, from, amount, to)
-									continue
-								}
-								batch.addTask(depositTask{
-									txid:      tx.Hash(),
-									requestId: requestId,
-								})
-							} else if isDesignateValidatorsEvent(event) {
-								pks, err := l.parseDesignateValidatorsEvent(event)
-								if err != nil {
-									panic(err)
-								}
-								log.Printf("validators designate event, pks=%s\n", pks)
-								batch.addTask(validatorsDesignateTask{
-									txid: tx.Hash(),
-								})
-							}
-						} else if l.isRoleManagement(event) {
-							isStateValidatorsDesignate, index, err := l.parseStateValidatorsDesignatedEvent(event)
-							if err != nil {
-								panic(err)
-							}
-							if isStateValidatorsDesignate {
-								log.Printf("state validators designate event, index=%d\n", index)
-								batch.addTask(stateValidatorsChangeTask{
-									txid:  tx.Hash(),
-									index: index,
-								})
-							}
-						}
-					}
+	}
+	l.runPipeline(sub)
+}
+
+// notificationsFromLog fetches the application log for every tx in block
+// and flattens the halted Application-trigger executions into the same
+// notification slice the websocket subscription gets for free from the
+// server.
+func (l *Relayer) notificationsFromLog(block *block.Block) []state.ContainedNotificationEvent {
+	var notifications []state.ContainedNotificationEvent
+	for _, tx := range block.Transactions {
+		log.Printf("syncing tx, hash=%s\n", tx.Hash())
+		applicationlog := l.client.GetApplicationLog(tx.Hash())
+		for _, execution := range applicationlog.Executions {
+			if execution.Trigger == trigger.Application && execution.VMState == vmstate.Halt {
+				for _, nevent := range execution.Events {
+					notifications = append(notifications, state.ContainedNotificationEvent{
+						Container:         tx.Hash(),
+						NotificationEvent: nevent,
+					})
 				}
 			}
 		}
-		err := l.sync(batch)
-		if err != nil {
-			panic(fmt.Errorf("can't sync block %d: %w", i, err))
+	}
+	return notifications
+}
+
+// buildBatch turns a block and its (already filtered) notifications into a
+// taskBatch. isJoint is deliberately left for commitStage to fill in: it
+// depends on l.lastHeader, which only commitStage is allowed to read or
+// write, since the analyze stage may run several blocks ahead of commit.
+func (l *Relayer) buildBatch(block *block.Block, notifications []state.ContainedNotificationEvent) *taskBatch {
+	batch := new(taskBatch)
+	batch.block = block
+	for i := range notifications {
+		container := notifications[i].Container
+		event := &notifications[i].NotificationEvent
+		if l.isManageContract(event) {
+			if isDepositEvent(event) {
+				requestId, from, amount, to, err := l.parseDepositEvent(event)
+				if err != nil {
+					l.deadLetter(container, i, event, err)
+					continue
+				}
+				log.Printf("deposit event, id=%d, from=%s, amount=%d, to=%s\n", requestId, from, amount, to)
+				if amount < MintThreshold {
+					log.Printf("threshold unreached, id=%d, from=%s, amount=%d, to=%s\n", requestId, from, amount, to)
+					continue
+				}
+				batch.addTask(depositTask{
+					txid:      container,
+					requestId: requestId,
+				})
+			} else if isDesignateValidatorsEvent(event) {
+				pks, err := l.parseDesignateValidatorsEvent(event)
+				if err != nil {
+					l.deadLetter(container, i, event, err)
+					continue
+				}
+				log.Printf("validators designate event, pks=%s\n", pks)
+				batch.addTask(validatorsDesignateTask{
+					txid: container,
+				})
+			}
+		} else if l.isRoleManagement(event) {
+			isStateValidatorsDesignate, index, err := l.parseStateValidatorsDesignatedEvent(event)
+			if err != nil {
+				l.deadLetter(container, i, event, err)
+				continue
+			}
+			if isStateValidatorsDesignate {
+				log.Printf("state validators designate event, index=%d\n", index)
+				batch.addTask(stateValidatorsChangeTask{
+					txid:  container,
+					index: index,
+				})
+			}
 		}
-		l.lastHeader = &block.Header
-		i++
 	}
+	return batch
 }
 
 func (l *Relayer) isJointHeader(header *block.Header) bool {
@@ -163,7 +237,10 @@ func (l *Relayer) parseStateValidatorsDesignatedEvent(event *state.NotificationE
 	if event.Name != "Designation" {
 		return false, 0, nil
 	}
-	arr := event.Item.Value().([]stackitem.Item)
+	arr, ok := event.Item.Value().([]stackitem.Item)
+	if !ok {
+		return false, 0, errors.New("invalid role deposite event item, expected an array")
+	}
 	if len(arr) != 2 {
 		return false, 0, errors.New("invalid role deposite event arguments count")
 	}
@@ -186,7 +263,11 @@ func isDepositEvent(event *state.NotificationEvent) bool {
 }
 
 func (l *Relayer) parseDepositEvent(event *state.NotificationEvent) (requestId uint64, from util.Uint160, amount uint64, to util.Uint160, err error) {
-	arr := event.Item.Value().([]stackitem.Item)
+	arr, ok := event.Item.Value().([]stackitem.Item)
+	if !ok {
+		err = errors.New("invalid deposited event item, expected an array")
+		return
+	}
 	if len(arr) != 4 {
 		err = errors.New("invalid deposited event arguments count")
 		return
@@ -213,7 +294,8 @@ func (l *Relayer) parseDepositEvent(event *state.NotificationEvent) (requestId u
 	}
 	from = bf
 	if arr[2].Type() != stackitem.IntegerT {
-		panic("invalid amount type in deposit event")
+		err = errors.New("invalid amount type in deposit event")
+		return
 	}
 	amt, err := arr[2].TryInteger()
 	if err != nil {
@@ -244,12 +326,20 @@ func isDesignateValidatorsEvent(event *state.NotificationEvent) bool {
 }
 
 func (l *Relayer) parseDesignateValidatorsEvent(event *state.NotificationEvent) (pks keys.PublicKeys, err error) {
-	arr := event.Item.Value().([]stackitem.Item)
+	arr, ok := event.Item.Value().([]stackitem.Item)
+	if !ok {
+		err = errors.New("invalid validators change event item, expected an array")
+		return
+	}
 	if len(arr) != 1 {
 		err = errors.New("invalid validators change arguments count")
 		return
 	}
-	arr = arr[0].Value().([]stackitem.Item)
+	arr, ok = arr[0].Value().([]stackitem.Item)
+	if !ok {
+		err = errors.New("invalid validators change event item, expected a nested array")
+		return
+	}
 	pks = make([]*keys.PublicKey, len(arr))
 	for i, p := range arr {
 		if p.Type() != stackitem.ByteArrayT {
@@ -262,7 +352,7 @@ func (l *Relayer) parseDesignateValidatorsEvent(event *state.NotificationEvent)
 			return
 		}
 		pt, e := keys.NewPublicKeyFromBytes(pkb, elliptic.P256())
-		if err != nil {
+		if e != nil {
 			err = fmt.Errorf("can't parse ecpoint: %w", e)
 			return
 		}
@@ -307,36 +397,64 @@ func (l *Relayer) sync(batch *taskBatch) error {
 	}
 	transactions = transactions[:0]
 	fmt.Println(len(batch.tasks))
-	for _, t := range batch.tasks {
-		var (
-			key    []byte
-			method string
-		)
-		switch v := t.(type) {
-		case depositTask:
-			method = CCMRequestMint
-			key = append([]byte{DepositPrefix}, big.NewInt(int64(v.requestId)).Bytes()...)
-		case validatorsDesignateTask:
-			method = CCMSyncValidators
-			key = []byte{ValidatorsKey}
-		case stateValidatorsChangeTask:
-			method = CCMSyncStateRootValidatorsAddress
-			key = make([]byte, 5)
-			key[0] = StateValidatorRole
-			binary.BigEndian.PutUint32(key[1:], v.index)
-		default:
-			return errors.New("unkown task")
-		}
-		tx, err := l.createStateSyncTransaction(method, batch.block, t.TxId(), stateroot, key)
-		if err != nil {
-			return err
+	if len(batch.tasks) > 0 {
+		hashes := make([]util.Uint256, len(batch.block.Transactions))
+		for i, tx := range batch.block.Transactions {
+			hashes[i] = tx.Hash()
 		}
-		if tx == nil { //synced already
-			continue
+		merkle := newTxMerkle(hashes)
+		for _, t := range batch.tasks {
+			var (
+				key    []byte
+				method string
+			)
+			switch v := t.(type) {
+			case depositTask:
+				method = CCMRequestMint
+				key = append([]byte{DepositPrefix}, big.NewInt(int64(v.requestId)).Bytes()...)
+			case validatorsDesignateTask:
+				method = CCMSyncValidators
+				key = []byte{ValidatorsKey}
+			case stateValidatorsChangeTask:
+				method = CCMSyncStateRootValidatorsAddress
+				key = make([]byte, 5)
+				key[0] = StateValidatorRole
+				binary.BigEndian.PutUint32(key[1:], v.index)
+			default:
+				return errors.New("unkown task")
+			}
+			tx, err := l.createStateSyncTransaction(method, batch.block, merkle, t.TxId(), stateroot, key)
+			if err != nil {
+				return err
+			}
+			if tx == nil { //synced already
+				continue
+			}
+			transactions = append(transactions, tx)
 		}
-		transactions = append(transactions, tx)
 	}
-	return l.commitTransactions(transactions)
+	if err := l.commitTransactions(transactions); err != nil {
+		return err
+	}
+	return l.saveCheckpoint(batch, stateroot)
+}
+
+// saveCheckpoint persists the cursor only after commitTransactions has
+// confirmed every tx in the batch, so a crash mid-batch is retried from
+// the previous cursor instead of skipping work that never landed. Most
+// batches carry no tasks and so never touch a state root; stateroot is
+// nil then, and LastStateRootIndex falls back to l.lastStateRoot so a
+// task-less block doesn't stomp the last verified state root index back
+// to zero.
+func (l *Relayer) saveCheckpoint(batch *taskBatch, stateroot *state.MPTRoot) error {
+	cursor := &Cursor{LastBlockIndex: batch.Index()}
+	switch {
+	case stateroot != nil:
+		cursor.LastStateRootIndex = stateroot.Index
+	case l.lastStateRoot != nil:
+		cursor.LastStateRootIndex = l.lastStateRoot.Index
+	}
+	return l.checkpoint.Save(cursor)
 }
 
 func (l *Relayer) getVerifiedStateRoot(index uint32) (*state.MPTRoot, error) {
@@ -358,12 +476,51 @@ func (l *Relayer) getVerifiedStateRoot(index uint32) (*state.MPTRoot, error) {
 	return nil, errors.New("can't get verified state root")
 }
 
-func (l *Relayer) invokeObjectSync(method string, object []byte) (*types.Transaction, error) {
+// submitSyncData builds the Ethereum tx for a sync call. When multi-signer
+// coordination is enabled and method has a threshold-signed entry point,
+// it routes through the Coordinator instead of submitting with this
+// relayer's own key; non-leader instances return (nil, nil) once they've
+// contributed their signature, the same way an "already synced" skip is
+// reported to callers.
+func (l *Relayer) submitSyncData(method string, calldata []byte, neoBlock uint32) (*types.Transaction, error) {
+	multiMethod, ok := multiSigMethod(method)
+	if l.coordinator == nil || !ok {
+		return l.createEthLayerTransaction(calldata)
+	}
+	nonce, err := l.coordinator.nextNonce()
+	if err != nil {
+		return nil, fmt.Errorf("can't allocate coordinator nonce: %w", err)
+	}
+	payload := &SignedSyncPayload{
+		Method:   method,
+		NeoBlock: neoBlock,
+		Nonce:    nonce,
+		Object:   calldata,
+	}
+	aggregated, err := l.coordinator.Collect(payload)
+	if err != nil {
+		return nil, fmt.Errorf("can't collect threshold signatures for %s: %w", method, err)
+	}
+	if aggregated == nil {
+		return nil, nil
+	}
+	signatures, err := json.Marshal(aggregated.Signatures)
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal signatures: %w", err)
+	}
+	data, err := l.bridge.Abi.Pack(multiMethod, aggregated.Object, signatures)
+	if err != nil {
+		return nil, fmt.Errorf("can't pack multi-sig payload, method=%s: %w", multiMethod, err)
+	}
+	return l.createEthLayerTransaction(data)
+}
+
+func (l *Relayer) invokeObjectSync(method string, object []byte, neoBlock uint32) (*types.Transaction, error) {
 	data, err := l.bridge.Abi.Pack(method, object)
 	if err != nil {
 		return nil, fmt.Errorf("can't pack sync object, method=%s: %w", method, err)
 	}
-	return l.createEthLayerTransaction(data)
+	return l.submitSyncData(method, data, neoBlock)
 }
 
 func (l *Relayer) createHeaderSyncTransaction(rpcHeader *block.Header) (*types.Transaction, error) {
@@ -371,7 +528,7 @@ func (l *Relayer) createHeaderSyncTransaction(rpcHeader *block.Header) (*types.T
 	if err != nil {
 		return nil, fmt.Errorf("can't encode block header: %w", err)
 	}
-	tx, err := l.invokeObjectSync(CCMSyncHeader, b)
+	tx, err := l.invokeObjectSync(CCMSyncHeader, b, uint32(rpcHeader.Index))
 	if err != nil {
 		if strings.Contains(err.Error(), CCMAlreadySyncedError) {
 			log.Println("skip synced header")
@@ -380,6 +537,9 @@ func (l *Relayer) createHeaderSyncTransaction(rpcHeader *block.Header) (*types.T
 			return nil, fmt.Errorf("can't %s, header=%s, h=%s,: %w", CCMSyncHeader, rpcHeader.Hash(), rpcHeader.Hash(), err)
 		}
 	}
+	if tx == nil { // non-leader in a multi-signer round: nothing to submit yet
+		return nil, nil
+	}
 	log.Printf("created %s tx, txid=%s\n", CCMSyncHeader, tx.Hash())
 	return tx, nil
 }
@@ -389,7 +549,7 @@ func (l *Relayer) createStateRootSyncTransaction(stateroot *state.MPTRoot) (*typ
 	if err != nil {
 		return nil, fmt.Errorf("can't encode stateroot: %w", err)
 	}
-	tx, err := l.invokeObjectSync(CCMSyncStateRoot, b)
+	tx, err := l.invokeObjectSync(CCMSyncStateRoot, b, stateroot.Index)
 	if err != nil {
 		if strings.Contains(err.Error(), CCMAlreadySyncedError) {
 			log.Println("skip synced state root")
@@ -407,14 +567,15 @@ func (l *Relayer) invokeStateSync(method string, index uint32, txid util.Uint256
 	if err != nil {
 		return nil, err
 	}
-	return l.createEthLayerTransaction(data)
+	return l.submitSyncData(method, data, index)
 }
 
-func (l *Relayer) createStateSyncTransaction(method string, block *block.Block, txid util.Uint256, stateroot *state.MPTRoot, key []byte) (*types.Transaction, error) {
-	txproof, err := proveTx(block, txid) // TODO: merkle tree reuse
+func (l *Relayer) createStateSyncTransaction(method string, block *block.Block, merkle *txMerkle, txid util.Uint256, stateroot *state.MPTRoot, key []byte) (*types.Transaction, error) {
+	proof, err := merkle.proof(txid)
 	if err != nil {
 		return nil, fmt.Errorf("can't build tx proof: %w", err)
 	}
+	txproof := proofBytes(proof)
 	stateproof := l.client.GetProof(stateroot.Root, l.cfg.BridgeContract, key)
 	if stateproof == nil {
 		return nil, errors.New("can't get state proof")
@@ -435,77 +596,144 @@ func (l *Relayer) createStateSyncTransaction(method string, block *block.Block,
 		}
 		return nil, err
 	}
+	if tx == nil { // non-leader in a multi-signer round: nothing to submit yet
+		return nil, nil
+	}
 	log.Printf("created %s tx, txid=%s\n", method, tx.Hash())
 	return tx, nil
 }
 
+// buildEthTx builds the unsigned side-chain tx for data using fee, either
+// a types.LegacyTx or a types.DynamicFeeTx depending on what fee carries.
+func (l *Relayer) buildEthTx(chainId *big.Int, nonce uint64, fee *FeeParams, gas uint64, data []byte) *types.Transaction {
+	if fee.Legacy {
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			To:       &(l.bridge.Address),
+			GasPrice: fee.GasPrice,
+			Gas:      gas,
+			Value:    big.NewInt(0),
+			Data:     data,
+		})
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainId,
+		Nonce:     nonce,
+		To:        &(l.bridge.Address),
+		GasTipCap: fee.GasTipCap,
+		GasFeeCap: fee.GasFeeCap,
+		Gas:       gas,
+		Value:     big.NewInt(0),
+		Data:      data,
+	})
+}
+
 func (l *Relayer) createEthLayerTransaction(data []byte) (*types.Transaction, error) {
-	var err error
 	chainId := l.client.Eth_ChainId()
-	gasPrice := l.client.Eth_GasPrice()
-	nonce := l.client.Eth_GetTransactionCount(l.account.Address)
-	ltx := &types.LegacyTx{
-		Nonce:    nonce,
-		To:       &(l.bridge.Address),
-		GasPrice: gasPrice,
-		Value:    big.NewInt(0),
-		Data:     data,
+	nonce, err := l.nextNonce()
+	if err != nil {
+		return nil, err
 	}
-	tx := &transaction.EthTx{
-		Transaction: *types.NewTx(ltx),
+	fee, err := l.fees.Prepare()
+	if err != nil {
+		return nil, fmt.Errorf("can't prepare fee: %w", err)
 	}
+	unsigned := l.buildEthTx(chainId, nonce, fee, 0, data)
 	gas, err := l.client.Eth_EstimateGas(&sresult.TransactionObject{
 		From:     l.account.Address,
-		To:       tx.To(),
-		GasPrice: tx.GasPrice(),
-		Value:    tx.Value(),
-		Data:     tx.Data(),
+		To:       unsigned.To(),
+		GasPrice: unsigned.GasPrice(),
+		Value:    unsigned.Value(),
+		Data:     unsigned.Data(),
 	})
 	if err != nil {
 		return nil, err
 	}
-	ltx.Gas = gas
-	tx.Transaction = *types.NewTx(ltx)
-	err = l.account.SignTx(chainId, transaction.NewTx(tx))
-	if err != nil {
+	tx := &transaction.EthTx{Transaction: *l.buildEthTx(chainId, nonce, fee, gas, data)}
+	if err := l.account.SignTx(chainId, transaction.NewTx(tx)); err != nil {
 		return nil, fmt.Errorf("can't sign tx: %w", err)
 	}
 	return &tx.Transaction, nil
 }
 
+// nextNonce hands out account nonces from a local counter instead of
+// calling Eth_GetTransactionCount before every tx, so pipelined batches
+// don't race each other for the same nonce. It is seeded from the chain
+// once, the first time it's needed.
+func (l *Relayer) nextNonce() (uint64, error) {
+	l.nonceMu.Lock()
+	defer l.nonceMu.Unlock()
+	if !l.nonceLoaded {
+		l.nonce = l.client.Eth_GetTransactionCount(l.account.Address)
+		l.nonceLoaded = true
+	}
+	n := l.nonce
+	l.nonce++
+	return n, nil
+}
+
+// commitTransactionDeadline is how long commitTransactions waits for a
+// batch to be mined before bumping gas price and resubmitting.
+const commitTransactionDeadline = 3 * BlockTimeSeconds * time.Second
+
 func (l *Relayer) commitTransactions(transactions []*types.Transaction) error {
 	if len(transactions) == 0 {
 		return nil
 	}
-	appending := make([]common.Hash, len(transactions))
-	for i, tx := range transactions {
-		b, err := tx.MarshalBinary()
-		if err != nil {
-			return err
-		}
-		h, err := l.client.Eth_SendRawTransaction(b)
-		if err != nil {
+	pending := append([]*types.Transaction{}, transactions...)
+	for _, tx := range pending {
+		if err := l.submit(tx); err != nil {
 			return err
 		}
-		appending[i] = h
 	}
-	retry := 3
-	for retry > 0 {
+	deadline := time.Now().Add(commitTransactionDeadline)
+	for len(pending) > 0 {
 		time.Sleep(BlockTimeSeconds * time.Second)
-		rest := make([]common.Hash, 0, len(appending))
-		for _, h := range appending {
-			txResp := l.client.Eth_GetTransactionByHash(h)
-			if txResp == nil {
-				rest = append(rest, h)
+		rest := pending[:0]
+		for _, tx := range pending {
+			if l.client.Eth_GetTransactionByHash(tx.Hash()) == nil {
+				rest = append(rest, tx)
 			}
 		}
-		if len(rest) == 0 {
-			return nil
+		pending = rest
+		if len(pending) == 0 || time.Now().Before(deadline) {
+			continue
+		}
+		log.Printf("%d tx(s) not mined within deadline, bumping gas and resubmitting", len(pending))
+		bumped := make([]*types.Transaction, len(pending))
+		for i, tx := range pending {
+			b, err := l.bumpGasAndResign(tx)
+			if err != nil {
+				return fmt.Errorf("can't bump gas for %s: %w", tx.Hash(), err)
+			}
+			if err := l.submit(b); err != nil {
+				return err
+			}
+			bumped[i] = b
 		}
-		appending = rest
-		retry--
+		pending = bumped
+		deadline = time.Now().Add(commitTransactionDeadline)
+	}
+	return nil
+}
+
+func (l *Relayer) submit(tx *types.Transaction) error {
+	b, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = l.client.Eth_SendRawTransaction(b)
+	return err
+}
+
+func (l *Relayer) bumpGasAndResign(tx *types.Transaction) (*types.Transaction, error) {
+	chainId := l.client.Eth_ChainId()
+	bumped := l.fees.Bump(feeParamsFromTx(tx))
+	resigned := &transaction.EthTx{Transaction: *l.buildEthTx(chainId, tx.Nonce(), bumped, tx.Gas(), tx.Data())}
+	if err := l.account.SignTx(chainId, transaction.NewTx(resigned)); err != nil {
+		return nil, fmt.Errorf("can't sign bumped tx: %w", err)
 	}
-	return fmt.Errorf("can't commit transactions: [%v]", appending)
+	return &resigned.Transaction, nil
 }
 
 type taskBatch struct {
@@ -0,0 +1,106 @@
+package relay
+
+import (
+	"errors"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	rpcclient "github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// blockEvent carries a freshly produced Neo block together with the
+// notifications the server matched against our bridge/role-management
+// filters, so Run doesn't need to call GetApplicationLog for txs that
+// can't possibly contain a relevant event.
+type blockEvent struct {
+	block         *block.Block
+	notifications []state.ContainedNotificationEvent
+}
+
+// subscription streams block/notification events over a single neo-go
+// websocket connection. It is only usable when the connected node exposes
+// the `/ws` RPC endpoint; callers fall back to polling otherwise.
+type subscription struct {
+	ws     *rpcclient.WSClient
+	blocks chan *block.Block
+	notifs chan state.ContainedNotificationEvent
+	events chan blockEvent
+}
+
+// subscribe opens a websocket RPC connection to the first reachable seed
+// and subscribes to block_added and notification_from_execution, the
+// latter filtered to the bridge and role management contracts so the
+// server does the event filtering for us instead of GetApplicationLog.
+func subscribe(seeds []string, bridgeContract, roleManagement util.Uint160) (*subscription, error) {
+	if len(seeds) == 0 {
+		return nil, errors.New("no seeds configured for subscription")
+	}
+	var (
+		ws  *rpcclient.WSClient
+		err error
+	)
+	for _, seed := range seeds {
+		ws, err = rpcclient.NewWS(seed, rpcclient.WSOptions{})
+		if err == nil {
+			break
+		}
+	}
+	if ws == nil {
+		return nil, err
+	}
+	s := &subscription{
+		ws:     ws,
+		blocks: make(chan *block.Block),
+		notifs: make(chan state.ContainedNotificationEvent),
+		events: make(chan blockEvent, 16),
+	}
+	if _, err := ws.ReceiveBlocks(nil, s.blocks); err != nil {
+		ws.Close()
+		return nil, err
+	}
+	for _, contract := range []util.Uint160{bridgeContract, roleManagement} {
+		hash := contract
+		if _, err := ws.ReceiveExecutionNotifications(&response.NotificationFilter{Contract: &hash}, s.notifs); err != nil {
+			ws.Close()
+			return nil, err
+		}
+	}
+	go s.run()
+	return s, nil
+}
+
+// run buffers notifications by the tx (container) they were emitted from,
+// then groups them onto the block that contains that tx once the block
+// itself arrives over the block_added stream. The two streams are
+// independent and not guaranteed to interleave in any particular order.
+func (s *subscription) run() {
+	defer close(s.events)
+	pending := map[util.Uint256][]state.ContainedNotificationEvent{}
+	for {
+		select {
+		case b, ok := <-s.blocks:
+			if !ok {
+				return
+			}
+			evt := blockEvent{block: b}
+			for _, tx := range b.Transactions {
+				evt.notifications = append(evt.notifications, pending[tx.Hash()]...)
+				delete(pending, tx.Hash())
+			}
+			s.events <- evt
+		case n, ok := <-s.notifs:
+			if !ok {
+				return
+			}
+			pending[n.Container] = append(pending[n.Container], n)
+		}
+	}
+}
+
+func (s *subscription) close() {
+	if s.ws != nil {
+		s.ws.Close()
+	}
+}